@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	"knative.dev/eventing/pkg/broker/ingress"
+	"knative.dev/pkg/logging"
+)
+
+// Dispatcher delivers a single event to a subscriber, retrying per a
+// RetryState's backoff schedule and, once retries are exhausted, forwarding
+// the event to the configured dead-letter sink.
+type Dispatcher struct {
+	sender ingress.Sender
+	retry  *RetryState
+}
+
+// NewDispatcher creates a Dispatcher that sends through sender and retries
+// per retry.
+func NewDispatcher(sender ingress.Sender, retry *RetryState) *Dispatcher {
+	return &Dispatcher{sender: sender, retry: retry}
+}
+
+// Dispatch delivers event to target. On failure it schedules a retry per
+// d.retry, honoring any Retry-After the subscriber returned (clamped to the
+// configured max), and blocks until the event is delivered, ctx is done, or
+// retries are exhausted — at which point it forwards the event to the
+// dead-letter sink, if one is configured.
+func (d *Dispatcher) Dispatch(ctx context.Context, target string, event cloudevents.Event) error {
+	logger := logging.FromContext(ctx)
+
+	for {
+		err := d.sender.Send(ctx, target, event)
+		if err == nil {
+			d.retry.Forget(event.ID())
+			return nil
+		}
+
+		nextAttempt, ok := d.retry.ShouldRetry(event.ID(), ingress.RetryAfterFrom(err))
+		if !ok {
+			logger.Warnw("dispatch: retries exhausted, routing to dead-letter sink",
+				"id", event.ID(), "target", target, "error", err)
+			return d.deadLetter(ctx, event, err)
+		}
+
+		if wait := time.Until(nextAttempt); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+	}
+}
+
+// deadLetter forwards event to the configured dead-letter sink. cause is
+// the error from the final failed delivery attempt, for error context if
+// there is no dead-letter sink or forwarding to it also fails.
+func (d *Dispatcher) deadLetter(ctx context.Context, event cloudevents.Event, cause error) error {
+	dls := d.retry.DeadLetterSink()
+	if dls == "" {
+		return fmt.Errorf("dispatch: retries exhausted and no dead-letter sink configured: %w", cause)
+	}
+	if err := d.sender.Send(ctx, dls, event); err != nil {
+		return fmt.Errorf("dispatch: delivery failed (%w) and dead-letter forwarding also failed: %v", cause, err)
+	}
+	return nil
+}
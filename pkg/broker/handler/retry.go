@@ -0,0 +1,175 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package handler implements the fanout dispatcher's retry/backoff state
+// machine: given a Broker or Trigger DeliverySpec, it schedules redelivery
+// attempts and, on exhaustion, forwards the event to a dead-letter sink.
+package handler
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+)
+
+// maxNextDelay caps the computed backoff delay returned by nextDelay, so a
+// Trigger with a large Retry count and exponential backoff can't grow the
+// schedule past a sane bound or overflow time.Duration's int64 nanoseconds.
+const maxNextDelay = 30 * time.Minute
+
+// Clock abstracts time.Now so tests can substitute a fake clock when
+// asserting on attempt scheduling.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock is the default Clock, backed by time.Now.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// attempt tracks the redelivery state for a single in-flight event, keyed
+// by the event's ID by the caller.
+type attempt struct {
+	count       int32
+	nextAttempt time.Time
+}
+
+// RetryState is a state machine that schedules delivery retries for events
+// dispatched to a single Trigger subscriber, according to a DeliverySpec. It
+// is keyed by event ID; callers call ShouldRetry after each failed delivery
+// and Forget after a successful one.
+type RetryState struct {
+	clock Clock
+	spec  eventingduckv1.DeliverySpec
+
+	// retryAfterMax bounds any Retry-After header returned by the
+	// subscriber, so a misconfigured or malicious sink can't stall the
+	// pipeline indefinitely. A value of 0 applies no bound.
+	retryAfterMax time.Duration
+
+	// mu guards attempts, which a single RetryState shares across every
+	// concurrent event a fanout Dispatcher delivers to the same Trigger
+	// subscriber.
+	mu       sync.Mutex
+	attempts map[string]*attempt
+}
+
+// NewRetryState creates a RetryState that honors spec and clamps any
+// Retry-After header to at most retryAfterMax. A retryAfterMax of 0 leaves
+// Retry-After unclamped.
+func NewRetryState(spec eventingduckv1.DeliverySpec, retryAfterMax time.Duration) *RetryState {
+	return &RetryState{
+		clock:         realClock{},
+		spec:          spec,
+		retryAfterMax: retryAfterMax,
+		attempts:      make(map[string]*attempt),
+	}
+}
+
+// maxRetry returns the configured retry count, defaulting to 0 (no retries)
+// when unset.
+func (r *RetryState) maxRetry() int32 {
+	if r.spec.Retry == nil {
+		return 0
+	}
+	return *r.spec.Retry
+}
+
+// baseDelay returns the configured backoff base delay, defaulting to 1s when
+// unset or unparsable.
+func (r *RetryState) baseDelay() time.Duration {
+	if r.spec.BackoffDelay == nil {
+		return time.Second
+	}
+	d, err := time.ParseDuration(*r.spec.BackoffDelay)
+	if err != nil {
+		return time.Second
+	}
+	return d
+}
+
+// ShouldRetry reports whether eventID has retry attempts remaining, and if
+// so records the attempt and returns the time at which it should be
+// redelivered, optionally clamped against a Retry-After header returned by
+// the previous attempt's response.
+func (r *RetryState) ShouldRetry(eventID string, retryAfter time.Duration) (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.attempts[eventID]
+	if !ok {
+		a = &attempt{}
+		r.attempts[eventID] = a
+	}
+	if a.count >= r.maxRetry() {
+		delete(r.attempts, eventID)
+		return time.Time{}, false
+	}
+
+	delay := r.nextDelay(a.count)
+	if retryAfter > 0 {
+		if retryAfter > r.retryAfterMax && r.retryAfterMax > 0 {
+			retryAfter = r.retryAfterMax
+		}
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+	}
+
+	a.count++
+	a.nextAttempt = r.clock.Now().Add(delay)
+	return a.nextAttempt, true
+}
+
+// nextDelay computes the backoff delay for the given 0-indexed attempt
+// count: min(maxNextDelay, base*(attempt+1)) for linear,
+// min(maxNextDelay, base*2^attempt) for exponential.
+func (r *RetryState) nextDelay(attemptCount int32) time.Duration {
+	base := r.baseDelay()
+	var d time.Duration
+	if r.spec.BackoffPolicy != nil && *r.spec.BackoffPolicy == eventingduckv1.BackoffPolicyExponential {
+		d = time.Duration(float64(base) * math.Pow(2, float64(attemptCount)))
+	} else {
+		d = base * time.Duration(attemptCount+1)
+	}
+	// d <= 0 catches float64->Duration overflow from a large attemptCount.
+	if d <= 0 || d > maxNextDelay {
+		return maxNextDelay
+	}
+	return d
+}
+
+// Forget discards any retry state held for eventID, e.g. after a successful
+// delivery.
+func (r *RetryState) Forget(eventID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.attempts, eventID)
+}
+
+// DeadLetterSink returns the dead-letter sink URI events should be forwarded
+// to once retries are exhausted: the spec's DeadLetterSink.URI, or "" if it
+// has none. A dead-letter sink given only as a Ref is not resolved here and
+// also yields "".
+func (r *RetryState) DeadLetterSink() string {
+	if r.spec.DeadLetterSink == nil || r.spec.DeadLetterSink.URI == nil {
+		return ""
+	}
+	return r.spec.DeadLetterSink.URI.String()
+}
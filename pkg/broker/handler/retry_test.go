@@ -0,0 +1,232 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	"knative.dev/pkg/apis"
+)
+
+// fakeClock is a Clock that only advances when told to, so tests can assert
+// on attempt scheduling without sleeping.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func backoffPolicy(p eventingduckv1.BackoffPolicyType) *eventingduckv1.BackoffPolicyType { return &p }
+
+func durationPtr(s string) *string { return &s }
+
+func TestShouldRetry_LinearBackoff(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	spec := eventingduckv1.DeliverySpec{
+		Retry:         int32Ptr(3),
+		BackoffPolicy: backoffPolicy(eventingduckv1.BackoffPolicyLinear),
+		BackoffDelay:  durationPtr("1s"),
+	}
+	rs := NewRetryState(spec, 0)
+	rs.clock = clock
+
+	wantDelays := []time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second}
+	for i, want := range wantDelays {
+		next, ok := rs.ShouldRetry("event-1", 0)
+		if !ok {
+			t.Fatalf("attempt %d: ShouldRetry() = false, want true", i)
+		}
+		if got := next.Sub(clock.now); got != want {
+			t.Fatalf("attempt %d: delay = %v, want %v", i, got, want)
+		}
+	}
+
+	if _, ok := rs.ShouldRetry("event-1", 0); ok {
+		t.Fatalf("ShouldRetry() after exhausting retries = true, want false")
+	}
+}
+
+func TestShouldRetry_ExponentialBackoff(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	spec := eventingduckv1.DeliverySpec{
+		Retry:         int32Ptr(4),
+		BackoffPolicy: backoffPolicy(eventingduckv1.BackoffPolicyExponential),
+		BackoffDelay:  durationPtr("1s"),
+	}
+	rs := NewRetryState(spec, 0)
+	rs.clock = clock
+
+	wantDelays := []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}
+	for i, want := range wantDelays {
+		next, ok := rs.ShouldRetry("event-1", 0)
+		if !ok {
+			t.Fatalf("attempt %d: ShouldRetry() = false, want true", i)
+		}
+		if got := next.Sub(clock.now); got != want {
+			t.Fatalf("attempt %d: delay = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestShouldRetry_ExponentialBackoffCapped(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	spec := eventingduckv1.DeliverySpec{
+		Retry:         int32Ptr(1),
+		BackoffPolicy: backoffPolicy(eventingduckv1.BackoffPolicyExponential),
+		// A 1-hour base with exponential backoff would otherwise grow well
+		// past maxNextDelay on the very first attempt.
+		BackoffDelay: durationPtr("1h"),
+	}
+	rs := NewRetryState(spec, 0)
+	rs.clock = clock
+
+	next, ok := rs.ShouldRetry("event-1", 0)
+	if !ok {
+		t.Fatalf("ShouldRetry() = false, want true")
+	}
+	if got := next.Sub(clock.now); got != maxNextDelay {
+		t.Fatalf("delay = %v, want capped at %v", got, maxNextDelay)
+	}
+}
+
+func TestShouldRetry_RetryAfterClamped(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	spec := eventingduckv1.DeliverySpec{
+		Retry:        int32Ptr(1),
+		BackoffDelay: durationPtr("1s"),
+	}
+	rs := NewRetryState(spec, 5*time.Second)
+	rs.clock = clock
+
+	// The subscriber asked for a 1-hour Retry-After; it must be clamped to
+	// the configured 5s max so a malicious/misconfigured sink can't stall
+	// the pipeline.
+	next, ok := rs.ShouldRetry("event-1", time.Hour)
+	if !ok {
+		t.Fatalf("ShouldRetry() = false, want true")
+	}
+	if got, want := next.Sub(clock.now), 5*time.Second; got != want {
+		t.Fatalf("delay = %v, want %v", got, want)
+	}
+}
+
+func TestShouldRetry_RetryAfterBelowBackoffIgnored(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	spec := eventingduckv1.DeliverySpec{
+		Retry:        int32Ptr(1),
+		BackoffDelay: durationPtr("10s"),
+	}
+	rs := NewRetryState(spec, time.Minute)
+	rs.clock = clock
+
+	// A 1s Retry-After is shorter than the 10s backoff delay; the backoff
+	// delay wins so delivery isn't retried faster than the policy allows.
+	next, ok := rs.ShouldRetry("event-1", time.Second)
+	if !ok {
+		t.Fatalf("ShouldRetry() = false, want true")
+	}
+	if got, want := next.Sub(clock.now), 10*time.Second; got != want {
+		t.Fatalf("delay = %v, want %v", got, want)
+	}
+}
+
+func TestDeadLetterSink_RoutedOnExhaustion(t *testing.T) {
+	dlsURI := &apis.URL{Scheme: "http", Host: "dls.example.com"}
+	spec := eventingduckv1.DeliverySpec{
+		Retry:          int32Ptr(0),
+		DeadLetterSink: &eventingduckv1.Destination{URI: dlsURI},
+	}
+	rs := NewRetryState(spec, 0)
+
+	if _, ok := rs.ShouldRetry("event-1", 0); ok {
+		t.Fatalf("ShouldRetry() with Retry=0 = true, want false")
+	}
+	if got, want := rs.DeadLetterSink(), dlsURI.String(); got != want {
+		t.Fatalf("DeadLetterSink() = %q, want %q", got, want)
+	}
+}
+
+func TestDeadLetterSink_EmptyWhenUnconfigured(t *testing.T) {
+	rs := NewRetryState(eventingduckv1.DeliverySpec{}, 0)
+	if got := rs.DeadLetterSink(); got != "" {
+		t.Fatalf("DeadLetterSink() = %q, want empty", got)
+	}
+}
+
+func TestForget_ClearsAttemptState(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	spec := eventingduckv1.DeliverySpec{
+		Retry:        int32Ptr(1),
+		BackoffDelay: durationPtr("1s"),
+	}
+	rs := NewRetryState(spec, 0)
+	rs.clock = clock
+
+	if _, ok := rs.ShouldRetry("event-1", 0); !ok {
+		t.Fatalf("ShouldRetry() = false, want true")
+	}
+	rs.Forget("event-1")
+
+	// After Forget, event-1 starts a fresh attempt count.
+	next, ok := rs.ShouldRetry("event-1", 0)
+	if !ok {
+		t.Fatalf("ShouldRetry() after Forget = false, want true")
+	}
+	if got, want := next.Sub(clock.now), time.Second; got != want {
+		t.Fatalf("delay after Forget = %v, want %v", got, want)
+	}
+}
+
+func TestRetryState_ConcurrentAccess(t *testing.T) {
+	spec := eventingduckv1.DeliverySpec{
+		Retry:        int32Ptr(3),
+		BackoffDelay: durationPtr("1ms"),
+	}
+	rs := NewRetryState(spec, 0)
+
+	// A single RetryState is shared across every event a fanout Dispatcher
+	// concurrently delivers to one Trigger's subscriber; ShouldRetry/Forget
+	// must be safe to call from many goroutines at once (run with -race).
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("event-%d", i)
+			for {
+				if _, ok := rs.ShouldRetry(id, 0); !ok {
+					return
+				}
+			}
+		}(i)
+	}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rs.Forget(fmt.Sprintf("event-%d", i))
+		}(i)
+	}
+	wg.Wait()
+}
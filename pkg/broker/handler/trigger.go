@@ -0,0 +1,69 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"encoding/json"
+	"time"
+
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	"knative.dev/eventing/pkg/apis/eventing/v1alpha1"
+	brokerreconciler "knative.dev/eventing/pkg/reconciler/broker"
+)
+
+// RetryAfterMaxAnnotationKey caps how long the fanout dispatcher will honor
+// a Retry-After header returned by a Trigger's subscriber, so a
+// misconfigured or malicious sink can't stall the pipeline. Written via
+// testing.WithTriggerRetryAfterMax.
+const RetryAfterMaxAnnotationKey = "eventing.knative.dev/retryAfterMax"
+
+// NewRetryStateForTrigger builds a RetryState from a Trigger's effective
+// DeliverySpec and its RetryAfterMaxAnnotationKey annotation, so both stay
+// in sync with whatever the dispatcher actually reads.
+func NewRetryStateForTrigger(t *v1alpha1.Trigger) *RetryState {
+	spec := effectiveDeliverySpec(t)
+
+	var retryAfterMax time.Duration
+	if v := t.GetAnnotations()[RetryAfterMaxAnnotationKey]; v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			retryAfterMax = d
+		}
+	}
+
+	return NewRetryState(spec, retryAfterMax)
+}
+
+// effectiveDeliverySpec returns the Broker-resolved DeliverySpec the Broker
+// reconciler recorded on t's brokerreconciler.EffectiveDeliveryAnnotationKey
+// annotation (the merge of the Broker's and this Trigger's own
+// Spec.Delivery, computed by broker.ResolveDelivery), so a Broker-level
+// retry/backoff/dead-letter-sink setting actually reaches live delivery.
+// The spec's Timeout is carried along but RetryState does not apply it.
+// Falls back to t's own Spec.Delivery if the annotation isn't set
+// or fails to parse, e.g. before the Broker reconciler has run.
+func effectiveDeliverySpec(t *v1alpha1.Trigger) eventingduckv1.DeliverySpec {
+	if encoded := t.GetAnnotations()[brokerreconciler.EffectiveDeliveryAnnotationKey]; encoded != "" {
+		var spec eventingduckv1.DeliverySpec
+		if err := json.Unmarshal([]byte(encoded), &spec); err == nil {
+			return spec
+		}
+	}
+	if t.Spec.Delivery != nil {
+		return *t.Spec.Delivery
+	}
+	return eventingduckv1.DeliverySpec{}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	"knative.dev/eventing/pkg/apis/eventing/v1alpha1"
+	brokerreconciler "knative.dev/eventing/pkg/reconciler/broker"
+)
+
+func TestNewRetryStateForTrigger_PrefersEffectiveDeliveryAnnotation(t *testing.T) {
+	effective := eventingduckv1.DeliverySpec{Retry: int32Ptr(7)}
+	encoded, err := json.Marshal(effective)
+	if err != nil {
+		t.Fatalf("json.Marshal() = %v", err)
+	}
+
+	trig := &v1alpha1.Trigger{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				brokerreconciler.EffectiveDeliveryAnnotationKey: string(encoded),
+			},
+		},
+		Spec: v1alpha1.TriggerSpec{
+			// The Trigger's own Spec.Delivery differs from the annotation;
+			// the Broker-resolved annotation must win.
+			Delivery: &eventingduckv1.DeliverySpec{Retry: int32Ptr(1)},
+		},
+	}
+
+	rs := NewRetryStateForTrigger(trig)
+	if got := rs.maxRetry(); got != 7 {
+		t.Errorf("maxRetry() = %d, want 7 (from the effective-delivery annotation)", got)
+	}
+}
+
+func TestNewRetryStateForTrigger_FallsBackToSpecDelivery(t *testing.T) {
+	trig := &v1alpha1.Trigger{
+		Spec: v1alpha1.TriggerSpec{
+			Delivery: &eventingduckv1.DeliverySpec{Retry: int32Ptr(2)},
+		},
+	}
+
+	rs := NewRetryStateForTrigger(trig)
+	if got := rs.maxRetry(); got != 2 {
+		t.Errorf("maxRetry() = %d, want 2 (from Spec.Delivery, no annotation set)", got)
+	}
+}
+
+func TestNewRetryStateForTrigger_RetryAfterMax(t *testing.T) {
+	trig := &v1alpha1.Trigger{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{RetryAfterMaxAnnotationKey: "5s"},
+		},
+	}
+
+	rs := NewRetryStateForTrigger(trig)
+	if got, want := rs.retryAfterMax, 5*time.Second; got != want {
+		t.Errorf("retryAfterMax = %v, want %v", got, want)
+	}
+}
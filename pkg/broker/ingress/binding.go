@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// Receiver accepts incoming events for a single namespace/broker pair over a
+// particular protocol binding (HTTP today; Kafka, NATS, or Pub/Sub in the
+// future) and hands each one to the supplied callback.
+//
+// Receive blocks until ctx is done or an unrecoverable transport error
+// occurs.
+type Receiver interface {
+	Receive(ctx context.Context, onEvent func(context.Context, cloudevents.Event) error) error
+}
+
+// Sender delivers a single event to a resolved subscriber URI over a
+// particular protocol binding. Implementations are expected to be safe for
+// concurrent use.
+type Sender interface {
+	Send(ctx context.Context, target string, event cloudevents.Event) error
+}
+
+// Binding names a pluggable protocol binding that Receiver/Sender
+// implementations may be registered under.
+type Binding string
+
+const (
+	// HTTPBinding is the default, and currently only, protocol binding.
+	HTTPBinding Binding = "HTTP"
+)
+
+// ReceiverFactory constructs a Receiver bound to a specific namespace.
+type ReceiverFactory func(ctx context.Context, client cloudevents.Client, namespace string) (Receiver, error)
+
+// SenderFactory constructs a Sender bound to a specific protocol binding.
+type SenderFactory func(ctx context.Context, client cloudevents.Client) (Sender, error)
+
+// bindings holds the registered ReceiverFactory/SenderFactory pairs, keyed by
+// Binding name. HTTP is registered by init() in http.go.
+var bindings = map[Binding]struct {
+	receiver ReceiverFactory
+	sender   SenderFactory
+}{}
+
+// RegisterBinding makes a protocol binding available to NewHandler via its
+// Binding name. It is intended to be called from the init() of a package
+// implementing that binding (see http.go for the HTTP binding).
+func RegisterBinding(name Binding, r ReceiverFactory, s SenderFactory) {
+	bindings[name] = struct {
+		receiver ReceiverFactory
+		sender   SenderFactory
+	}{receiver: r, sender: s}
+}
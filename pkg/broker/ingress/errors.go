@@ -0,0 +1,42 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"errors"
+	"time"
+)
+
+// RetryAfterError wraps a failed delivery, carrying the Retry-After
+// duration the subscriber's response requested, if any. Dispatchers can
+// extract it with RetryAfterFrom to clamp their own backoff.
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string { return e.Err.Error() }
+func (e *RetryAfterError) Unwrap() error { return e.Err }
+
+// RetryAfterFrom extracts the Retry-After duration carried by err, if any.
+func RetryAfterFrom(err error) time.Duration {
+	var rae *RetryAfterError
+	if errors.As(err, &rae) {
+		return rae.RetryAfter
+	}
+	return 0
+}
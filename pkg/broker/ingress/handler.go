@@ -0,0 +1,123 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ingress implements the Broker ingress and filter data plane on top
+// of the cloudevents/sdk-go/v2 client abstraction. Transports are pluggable
+// protocol Bindings (see binding.go); only HTTP is wired up today, but Kafka,
+// NATS, or Pub/Sub bindings can be added without touching Handler itself.
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"knative.dev/pkg/logging"
+)
+
+// Handler receives events for zero or more namespaces over a single protocol
+// Binding, applies a Filter to each one, and forwards matching events to a
+// resolved subscriber URI via a Sender.
+type Handler struct {
+	client  cloudevents.Client
+	binding Binding
+	sender  Sender
+
+	// mu guards receivers, which ReceiveFrom writes to. ReceiveFrom is
+	// typically called concurrently, once per namespace.
+	mu        sync.RWMutex
+	receivers map[string]Receiver
+
+	// Filter reports whether event should be forwarded to target for the
+	// given namespace. It is called once per (namespace, event) pair.
+	Filter func(ctx context.Context, namespace string, event cloudevents.Event) (target string, ok bool)
+}
+
+// NewHandler creates a Handler that receives and sends events over the named
+// protocol binding using client.
+func NewHandler(ctx context.Context, client cloudevents.Client, binding Binding) (*Handler, error) {
+	b, ok := bindings[binding]
+	if !ok {
+		return nil, fmt.Errorf("ingress: no protocol binding registered for %q", binding)
+	}
+	sender, err := b.sender(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("ingress: constructing sender for %q: %w", binding, err)
+	}
+	return &Handler{
+		client:    client,
+		binding:   binding,
+		sender:    sender,
+		receivers: make(map[string]Receiver),
+	}, nil
+}
+
+// ReceiveFrom starts receiving events for namespace, blocking until ctx is
+// done. Each accepted event is passed to Filter; if Filter reports a target,
+// the event is forwarded via the Handler's Sender.
+func (h *Handler) ReceiveFrom(ctx context.Context, namespace string) error {
+	b := bindings[h.binding]
+	receiver, err := b.receiver(ctx, h.client, namespace)
+	if err != nil {
+		return fmt.Errorf("ingress: constructing receiver for %q/%q: %w", h.binding, namespace, err)
+	}
+
+	h.mu.Lock()
+	h.receivers[namespace] = receiver
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.receivers, namespace)
+		h.mu.Unlock()
+	}()
+
+	return receiver.Receive(ctx, func(ctx context.Context, event cloudevents.Event) error {
+		return h.dispatch(ctx, namespace, event)
+	})
+}
+
+// Namespaces returns the namespaces currently being received from, e.g. for
+// readiness reporting.
+func (h *Handler) Namespaces() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	namespaces := make([]string, 0, len(h.receivers))
+	for ns := range h.receivers {
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces
+}
+
+func (h *Handler) dispatch(ctx context.Context, namespace string, event cloudevents.Event) error {
+	logger := logging.FromContext(ctx)
+
+	if h.Filter == nil {
+		logger.Debugw("ingress: no Filter configured, dropping event", "id", event.ID())
+		return nil
+	}
+
+	target, ok := h.Filter(ctx, namespace, event)
+	if !ok {
+		return nil
+	}
+
+	if err := h.sender.Send(ctx, target, event); err != nil {
+		logger.Errorw("ingress: failed to forward event", "id", event.ID(), "target", target, "error", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+type fakeSender struct {
+	mu      sync.Mutex
+	sent    []string
+	sendErr error
+}
+
+func (s *fakeSender) Send(ctx context.Context, target string, event cloudevents.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sendErr != nil {
+		return s.sendErr
+	}
+	s.sent = append(s.sent, target)
+	return nil
+}
+
+func newTestEvent() cloudevents.Event {
+	e := cloudevents.NewEvent()
+	e.SetID("test-event")
+	e.SetType("test.type")
+	e.SetSource("test/source")
+	return e
+}
+
+func TestDispatch_NoFilterDrops(t *testing.T) {
+	sender := &fakeSender{}
+	h := &Handler{sender: sender, receivers: make(map[string]Receiver)}
+
+	if err := h.dispatch(context.Background(), "ns", newTestEvent()); err != nil {
+		t.Fatalf("dispatch() = %v, want nil", err)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("sender.sent = %v, want empty", sender.sent)
+	}
+}
+
+func TestDispatch_FilterRejects(t *testing.T) {
+	sender := &fakeSender{}
+	h := &Handler{
+		sender:    sender,
+		receivers: make(map[string]Receiver),
+		Filter: func(ctx context.Context, namespace string, event cloudevents.Event) (string, bool) {
+			return "", false
+		},
+	}
+
+	if err := h.dispatch(context.Background(), "ns", newTestEvent()); err != nil {
+		t.Fatalf("dispatch() = %v, want nil", err)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("sender.sent = %v, want empty", sender.sent)
+	}
+}
+
+func TestDispatch_FilterAcceptsForwardsToSender(t *testing.T) {
+	sender := &fakeSender{}
+	h := &Handler{
+		sender:    sender,
+		receivers: make(map[string]Receiver),
+		Filter: func(ctx context.Context, namespace string, event cloudevents.Event) (string, bool) {
+			return "http://subscriber.example.com", true
+		},
+	}
+
+	if err := h.dispatch(context.Background(), "ns", newTestEvent()); err != nil {
+		t.Fatalf("dispatch() = %v, want nil", err)
+	}
+	if got, want := sender.sent, []string{"http://subscriber.example.com"}; len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("sender.sent = %v, want %v", got, want)
+	}
+}
+
+func TestDispatch_SendErrorPropagates(t *testing.T) {
+	wantErr := errors.New("boom")
+	sender := &fakeSender{sendErr: wantErr}
+	h := &Handler{
+		sender:    sender,
+		receivers: make(map[string]Receiver),
+		Filter: func(ctx context.Context, namespace string, event cloudevents.Event) (string, bool) {
+			return "http://subscriber.example.com", true
+		},
+	}
+
+	if err := h.dispatch(context.Background(), "ns", newTestEvent()); !errors.Is(err, wantErr) {
+		t.Fatalf("dispatch() = %v, want %v", err, wantErr)
+	}
+}
+
+// blockingReceiver blocks until ctx is done, so ReceiveFrom's registration
+// of the namespace in h.receivers can be observed by a concurrent reader.
+type blockingReceiver struct{}
+
+func (blockingReceiver) Receive(ctx context.Context, onEvent func(context.Context, cloudevents.Event) error) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestNamespaces_ConcurrentReceiveFrom(t *testing.T) {
+	const binding Binding = "fake-test-binding"
+	RegisterBinding(binding, func(ctx context.Context, client cloudevents.Client, namespace string) (Receiver, error) {
+		return blockingReceiver{}, nil
+	}, func(ctx context.Context, client cloudevents.Client) (Sender, error) {
+		return &fakeSender{}, nil
+	})
+
+	h := &Handler{binding: binding, receivers: make(map[string]Receiver)}
+
+	const n = 8
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			h.ReceiveFrom(ctx, fmt.Sprintf("ns-%d", i))
+		}(i)
+	}
+
+	// Race detector exercises the mutex added around h.receivers as these
+	// goroutines register concurrently; Namespaces reads it back
+	// concurrently too.
+	for i := 0; i < 100; i++ {
+		h.Namespaces()
+	}
+
+	cancel()
+	wg.Wait()
+
+	if got := len(h.Namespaces()); got != 0 {
+		t.Fatalf("Namespaces() after cancel = %d entries, want 0", got)
+	}
+}
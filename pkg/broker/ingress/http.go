@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+func init() {
+	RegisterBinding(HTTPBinding, newHTTPReceiver, newHTTPSender)
+}
+
+// httpReceiver adapts an HTTP-bound cloudevents.Client into a Receiver for a
+// single namespace.
+type httpReceiver struct {
+	client    cloudevents.Client
+	namespace string
+}
+
+func newHTTPReceiver(ctx context.Context, client cloudevents.Client, namespace string) (Receiver, error) {
+	return &httpReceiver{client: client, namespace: namespace}, nil
+}
+
+func (r *httpReceiver) Receive(ctx context.Context, onEvent func(context.Context, cloudevents.Event) error) error {
+	return r.client.StartReceiver(ctx, func(ctx context.Context, event cloudevents.Event) error {
+		return onEvent(ctx, event)
+	})
+}
+
+// httpSender adapts an HTTP-bound cloudevents.Client into a Sender.
+type httpSender struct {
+	client cloudevents.Client
+}
+
+func newHTTPSender(ctx context.Context, client cloudevents.Client) (Sender, error) {
+	return &httpSender{client: client}, nil
+}
+
+func (s *httpSender) Send(ctx context.Context, target string, event cloudevents.Event) error {
+	ctx = cloudevents.ContextWithTarget(ctx, target)
+	result := s.client.Send(ctx, event)
+
+	// IsACK is only true for a successful (2xx) response; unlike
+	// IsUndelivered, it also catches a subscriber responding with a 4xx/5xx,
+	// which must be retried rather than reported as delivered.
+	if cloudevents.IsACK(result) {
+		return nil
+	}
+	return &RetryAfterError{Err: result, RetryAfter: retryAfterFromResult(result)}
+}
+
+// retryAfterFromResult extracts a Retry-After header from an HTTP protocol
+// result, if the subscriber returned one.
+func retryAfterFromResult(result protocol.Result) time.Duration {
+	var httpResult *cehttp.Result
+	if !cloudevents.ResultAs(result, &httpResult) || httpResult == nil {
+		return 0
+	}
+	v := httpResult.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+func TestRetryAfterFromResult(t *testing.T) {
+	cases := map[string]struct {
+		result *cehttp.Result
+		want   time.Duration
+	}{
+		"retry-after present": {
+			result: &cehttp.Result{Header: http.Header{"Retry-After": []string{"30"}}},
+			want:   30 * time.Second,
+		},
+		"retry-after absent": {
+			result: &cehttp.Result{Header: http.Header{}},
+			want:   0,
+		},
+		"retry-after not a number": {
+			result: &cehttp.Result{Header: http.Header{"Retry-After": []string{"not-a-number"}}},
+			want:   0,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := retryAfterFromResult(tc.result); got != tc.want {
+				t.Errorf("retryAfterFromResult() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfterFromResult_NonHTTPResult(t *testing.T) {
+	// A non-cehttp.Result (e.g. a plain error) carries no Retry-After.
+	if got := retryAfterFromResult(nil); got != 0 {
+		t.Errorf("retryAfterFromResult(nil) = %v, want 0", got)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	eventingclientset "knative.dev/eventing/pkg/client/clientset/versioned"
+	brokerreconciler "knative.dev/eventing/pkg/client/injection/reconciler/eventing/v1beta1/broker"
+	eventinglisters "knative.dev/eventing/pkg/client/listers/eventing/v1beta1"
+	reconciler "knative.dev/pkg/reconciler"
+)
+
+// BrokerReconciler is the producer-side half of the discovery subsystem: it
+// keeps a Broker's DiscoveredEventTypesAnnotationKey annotation in sync with
+// the EventTypes Reconciler (the Trigger-side half, in reconciler.go) has
+// created for it, so Lister.Graph's Producers map is populated in
+// production rather than only by the WithBrokerDiscoveredEventTypes
+// testing option.
+type BrokerReconciler struct {
+	eventingClientSet eventingclientset.Interface
+	eventTypeLister   eventinglisters.EventTypeLister
+}
+
+var _ brokerreconciler.Interface = (*BrokerReconciler)(nil)
+
+// ReconcileKind implements brokerreconciler.Interface.
+func (r *BrokerReconciler) ReconcileKind(ctx context.Context, b *eventingv1beta1.Broker) reconciler.Event {
+	selector := labels.SelectorFromSet(labels.Set{eventTypeBrokerLabel: b.Name})
+	ets, err := r.eventTypeLister.EventTypes(b.Namespace).List(selector)
+	if err != nil {
+		return fmt.Errorf("listing event types for broker %s/%s: %w", b.Namespace, b.Name, err)
+	}
+
+	seen := make(map[string]bool, len(ets))
+	tokens := make([]string, 0, len(ets))
+	for _, et := range ets {
+		var source string
+		if et.Spec.Source != nil {
+			source = et.Spec.Source.String()
+		}
+		tok := eventTypeToken(et.Spec.Type, source)
+		if seen[tok] {
+			continue
+		}
+		seen[tok] = true
+		tokens = append(tokens, tok)
+	}
+	sort.Strings(tokens)
+
+	if err := r.patchDiscoveredEventTypesAnnotation(ctx, b, strings.Join(tokens, ",")); err != nil {
+		return fmt.Errorf("annotating discovered event types for broker %s/%s: %w", b.Namespace, b.Name, err)
+	}
+	return nil
+}
+
+// patchDiscoveredEventTypesAnnotation merge-patches b's
+// DiscoveredEventTypesAnnotationKey annotation to discovered, if it has
+// changed.
+func (r *BrokerReconciler) patchDiscoveredEventTypesAnnotation(ctx context.Context, b *eventingv1beta1.Broker, discovered string) error {
+	if b.GetAnnotations()[DiscoveredEventTypesAnnotationKey] == discovered {
+		return nil
+	}
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, DiscoveredEventTypesAnnotationKey, discovered))
+	_, err := r.eventingClientSet.EventingV1beta1().Brokers(b.Namespace).Patch(ctx, b.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
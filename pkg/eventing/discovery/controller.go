@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	eventingclient "knative.dev/eventing/pkg/client/injection/client"
+	brokerinformer "knative.dev/eventing/pkg/client/injection/informers/eventing/v1beta1/broker"
+	eventtypeinformer "knative.dev/eventing/pkg/client/injection/informers/eventing/v1beta1/eventtype"
+	triggerinformer "knative.dev/eventing/pkg/client/injection/informers/eventing/v1beta1/trigger"
+	brokerreconciler "knative.dev/eventing/pkg/client/injection/reconciler/eventing/v1beta1/broker"
+	triggerreconciler "knative.dev/eventing/pkg/client/injection/reconciler/eventing/v1beta1/trigger"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+)
+
+// NewTriggerController creates the consumer-side half of the discovery
+// subsystem: for each Trigger it ensures a matching EventType exists and
+// annotates the Trigger with the EventTypes it consumes.
+func NewTriggerController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+	r := &Reconciler{
+		eventingClientSet: eventingclient.Get(ctx),
+		eventTypeLister:   eventtypeinformer.Get(ctx).Lister(),
+	}
+	impl := triggerreconciler.NewImpl(ctx, r)
+
+	triggerinformer.Get(ctx).Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+
+	return impl
+}
+
+// NewBrokerController creates the producer-side half of the discovery
+// subsystem: it keeps each Broker's DiscoveredEventTypesAnnotationKey
+// annotation in sync with the EventTypes NewTriggerController creates for
+// it.
+func NewBrokerController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+	eventTypeInformer := eventtypeinformer.Get(ctx)
+
+	r := &BrokerReconciler{
+		eventingClientSet: eventingclient.Get(ctx),
+		eventTypeLister:   eventTypeInformer.Lister(),
+	}
+	impl := brokerreconciler.NewImpl(ctx, r)
+
+	brokerinformer.Get(ctx).Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+
+	// Re-enqueue the owning Broker whenever one of its EventTypes changes,
+	// so a newly created/updated EventType is reflected onto the Broker's
+	// annotation promptly instead of waiting for the Broker's own resync.
+	eventTypeInformer.Informer().AddEventHandler(controller.HandleAll(func(obj interface{}) {
+		et, ok := obj.(*eventingv1beta1.EventType)
+		if !ok {
+			return
+		}
+		impl.EnqueueKey(types.NamespacedName{Namespace: et.Namespace, Name: et.Spec.Broker})
+	}))
+
+	return impl
+}
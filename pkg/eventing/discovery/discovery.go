@@ -0,0 +1,98 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package discovery builds a machine-readable producer→eventtype→consumer
+// graph from the Triggers and Brokers in a namespace, so that external
+// catalogs and UI tools can render event-mesh topology without re-deriving
+// it from CloudEvents traffic.
+package discovery
+
+import "strings"
+
+// ConsumedEventTypesAnnotationKey is written on a Trigger by the discovery
+// controller, enumerating the EventTypes it subscribes to.
+const ConsumedEventTypesAnnotationKey = "eventing.knative.dev/consumedEventTypes"
+
+// DiscoveredEventTypesAnnotationKey is written on a Broker by the discovery
+// controller (BrokerReconciler, in broker_reconciler.go), enumerating the
+// EventTypes labeled with that Broker, i.e. those Reconciler created from
+// the filters of the Broker's Triggers.
+const DiscoveredEventTypesAnnotationKey = "eventing.knative.dev/discoveredEventTypes"
+
+// eventTypeToken encodes a (type, source) pair as a single comma-list entry
+// for DiscoveredEventTypesAnnotationKey, so the producer keys Lister.Graph
+// builds from it carry the same Source field the consumer keys built from a
+// Trigger's filter do. A bare type with no source round-trips unchanged,
+// so the WithBrokerDiscoveredEventTypes testing option (which only ever
+// writes types) keeps working.
+func eventTypeToken(typ, source string) string {
+	if source == "" {
+		return typ
+	}
+	return typ + "|" + source
+}
+
+// parseEventTypeToken decodes a token written by eventTypeToken.
+func parseEventTypeToken(tok string) (typ, source string) {
+	if i := strings.IndexByte(tok, '|'); i >= 0 {
+		return tok[:i], tok[i+1:]
+	}
+	return tok, ""
+}
+
+// EventTypeKey identifies an EventType by the (type, source, broker) tuple
+// it was discovered from.
+type EventTypeKey struct {
+	Type   string
+	Source string
+	Broker string
+}
+
+// Edge is a single producer-or-consumer relationship to an EventTypeKey.
+type Edge struct {
+	// Namespace/Name of the Trigger or other resource on the other end of
+	// the edge.
+	Namespace string
+	Name      string
+}
+
+// Graph is a snapshot of the event-mesh topology for a namespace: for each
+// discovered EventTypeKey, which Broker produces it and which Triggers
+// consume it.
+type Graph struct {
+	// Producers maps an EventTypeKey to the Broker that produces it.
+	Producers map[EventTypeKey]Edge
+
+	// Consumers maps an EventTypeKey to the Triggers subscribed to it.
+	Consumers map[EventTypeKey][]Edge
+}
+
+// ConsumersOf returns the Triggers consuming the given EventTypeKey, if any.
+func (g *Graph) ConsumersOf(key EventTypeKey) []Edge {
+	if g == nil {
+		return nil
+	}
+	return g.Consumers[key]
+}
+
+// ProducerOf returns the Broker producing the given EventTypeKey, if known.
+func (g *Graph) ProducerOf(key EventTypeKey) (Edge, bool) {
+	if g == nil {
+		return Edge{}, false
+	}
+	e, ok := g.Producers[key]
+	return e, ok
+}
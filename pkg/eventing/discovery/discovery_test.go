@@ -0,0 +1,67 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import "testing"
+
+func TestEventTypeTokenRoundTrip(t *testing.T) {
+	cases := []struct {
+		typ, source string
+	}{
+		{typ: "dev.knative.foo"},
+		{typ: "dev.knative.foo", source: "/apis/v1/namespaces/ns/pods/p"},
+	}
+	for _, tc := range cases {
+		tok := eventTypeToken(tc.typ, tc.source)
+		gotType, gotSource := parseEventTypeToken(tok)
+		if gotType != tc.typ || gotSource != tc.source {
+			t.Errorf("parseEventTypeToken(eventTypeToken(%q, %q)) = (%q, %q), want (%q, %q)",
+				tc.typ, tc.source, gotType, gotSource, tc.typ, tc.source)
+		}
+	}
+}
+
+func TestGraph_ProducerAndConsumerKeysAgree(t *testing.T) {
+	key := EventTypeKey{Type: "dev.knative.foo", Source: "a-source", Broker: "default"}
+	g := &Graph{
+		Producers: map[EventTypeKey]Edge{
+			key: {Namespace: "ns", Name: "default"},
+		},
+		Consumers: map[EventTypeKey][]Edge{
+			key: {{Namespace: "ns", Name: "trigger-1"}},
+		},
+	}
+
+	producer, ok := g.ProducerOf(key)
+	if !ok || producer.Name != "default" {
+		t.Fatalf("ProducerOf(%v) = %v, %v; want the default broker", key, producer, ok)
+	}
+	consumers := g.ConsumersOf(key)
+	if len(consumers) != 1 || consumers[0].Name != "trigger-1" {
+		t.Fatalf("ConsumersOf(%v) = %v, want [trigger-1]", key, consumers)
+	}
+}
+
+func TestGraph_NilSafe(t *testing.T) {
+	var g *Graph
+	if _, ok := g.ProducerOf(EventTypeKey{}); ok {
+		t.Error("ProducerOf on nil Graph = true, want false")
+	}
+	if got := g.ConsumersOf(EventTypeKey{}); got != nil {
+		t.Errorf("ConsumersOf on nil Graph = %v, want nil", got)
+	}
+}
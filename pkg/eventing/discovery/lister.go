@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	eventinglisters "knative.dev/eventing/pkg/client/listers/eventing/v1beta1"
+)
+
+// Lister returns the event-mesh topology graph for a namespace, built from
+// the Triggers and Brokers currently known to the informer caches backing
+// it.
+type Lister interface {
+	// Graph returns the producer→eventtype→consumer graph for namespace.
+	Graph(namespace string) (*Graph, error)
+}
+
+// lister is the default Lister, backed by the generated Trigger and Broker
+// listers.
+type lister struct {
+	triggerLister eventinglisters.TriggerLister
+	brokerLister  eventinglisters.BrokerLister
+}
+
+// NewLister returns a Lister backed by the given generated listers.
+func NewLister(triggerLister eventinglisters.TriggerLister, brokerLister eventinglisters.BrokerLister) Lister {
+	return &lister{triggerLister: triggerLister, brokerLister: brokerLister}
+}
+
+// Graph implements Lister.
+func (l *lister) Graph(namespace string) (*Graph, error) {
+	triggers, err := l.triggerLister.Triggers(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	brokers, err := l.brokerLister.Brokers(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Graph{
+		Producers: make(map[EventTypeKey]Edge, len(brokers)),
+		Consumers: make(map[EventTypeKey][]Edge, len(triggers)),
+	}
+
+	for _, b := range brokers {
+		discovered := b.GetAnnotations()[DiscoveredEventTypesAnnotationKey]
+		if discovered == "" {
+			continue
+		}
+		for _, tok := range strings.Split(discovered, ",") {
+			if tok == "" {
+				continue
+			}
+			typ, source := parseEventTypeToken(tok)
+			key := EventTypeKey{Type: typ, Source: source, Broker: b.Name}
+			g.Producers[key] = Edge{Namespace: b.Namespace, Name: b.Name}
+		}
+	}
+
+	for _, t := range triggers {
+		// A Trigger with no Filter matches every event ("match-all"), so it
+		// has no single EventTypeKey to record it against.
+		if t.Spec.Filter == nil {
+			continue
+		}
+		attrs := t.Spec.Filter.Attributes
+		key := EventTypeKey{
+			Type:   attrs["type"],
+			Source: attrs["source"],
+			Broker: t.Spec.Broker,
+		}
+		g.Consumers[key] = append(g.Consumers[key], Edge{Namespace: t.Namespace, Name: t.Name})
+	}
+	return g, nil
+}
+
+var _ Lister = (*lister)(nil)
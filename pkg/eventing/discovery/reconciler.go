@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	eventingclientset "knative.dev/eventing/pkg/client/clientset/versioned"
+	triggerreconciler "knative.dev/eventing/pkg/client/injection/reconciler/eventing/v1beta1/trigger"
+	eventinglisters "knative.dev/eventing/pkg/client/listers/eventing/v1beta1"
+	"knative.dev/pkg/apis"
+	reconciler "knative.dev/pkg/reconciler"
+)
+
+// eventTypeBrokerLabel and eventTypeTypeLabel are written on every EventType
+// this Reconciler creates, so it can find the EventType for a given
+// (broker, type) pair without needing a deterministic name.
+const (
+	eventTypeBrokerLabel = "eventing.knative.dev/broker"
+	eventTypeTypeLabel   = "eventing.knative.dev/type"
+)
+
+// Reconciler watches Triggers and keeps the discovered event-mesh topology
+// up to date: for each Trigger's filter it ensures an EventType exists for
+// the filter's (broker, type) pair, and annotates the Trigger with the
+// EventTypes it consumes. The source recorded on that EventType is the one
+// of the Trigger that first created it; a Trigger with the same broker and
+// type but another source gets no EventType of its own.
+type Reconciler struct {
+	eventingClientSet eventingclientset.Interface
+	eventTypeLister   eventinglisters.EventTypeLister
+}
+
+var _ triggerreconciler.Interface = (*Reconciler)(nil)
+
+// ReconcileKind implements triggerreconciler.Interface.
+func (r *Reconciler) ReconcileKind(ctx context.Context, t *eventingv1beta1.Trigger) reconciler.Event {
+	if t.Spec.Filter == nil {
+		// A match-all Trigger doesn't pin down a single EventTypeKey to
+		// discover or annotate.
+		return nil
+	}
+
+	key := EventTypeKey{
+		Type:   t.Spec.Filter.Attributes["type"],
+		Source: t.Spec.Filter.Attributes["source"],
+		Broker: t.Spec.Broker,
+	}
+	if key.Type == "" {
+		return nil
+	}
+
+	if err := r.ensureEventType(ctx, t.Namespace, key); err != nil {
+		return fmt.Errorf("ensuring EventType for trigger %s/%s: %w", t.Namespace, t.Name, err)
+	}
+
+	if err := r.patchConsumedEventTypesAnnotation(ctx, t, key); err != nil {
+		return fmt.Errorf("annotating consumed event types for trigger %s/%s: %w", t.Namespace, t.Name, err)
+	}
+
+	return nil
+}
+
+// ensureEventType creates the EventType for key if one labeled with its
+// (broker, type) pair doesn't already exist in namespace.
+func (r *Reconciler) ensureEventType(ctx context.Context, namespace string, key EventTypeKey) error {
+	selector := labels.SelectorFromSet(labels.Set{
+		eventTypeBrokerLabel: key.Broker,
+		eventTypeTypeLabel:   key.Type,
+	})
+	existing, err := r.eventTypeLister.EventTypes(namespace).List(selector)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return nil
+	}
+
+	et := &eventingv1beta1.EventType{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "et-",
+			Namespace:    namespace,
+			Labels: map[string]string{
+				eventTypeBrokerLabel: key.Broker,
+				eventTypeTypeLabel:   key.Type,
+			},
+		},
+		Spec: eventingv1beta1.EventTypeSpec{
+			Type:   key.Type,
+			Broker: key.Broker,
+		},
+	}
+	if key.Source != "" {
+		if u, err := apis.ParseURL(key.Source); err == nil {
+			et.Spec.Source = u
+		}
+	}
+	_, err = r.eventingClientSet.EventingV1beta1().EventTypes(namespace).Create(ctx, et, metav1.CreateOptions{})
+	return err
+}
+
+// patchConsumedEventTypesAnnotation adds key.Type to t's
+// ConsumedEventTypesAnnotationKey annotation, if it isn't already present.
+func (r *Reconciler) patchConsumedEventTypesAnnotation(ctx context.Context, t *eventingv1beta1.Trigger, key EventTypeKey) error {
+	var existing []string
+	for _, v := range strings.Split(t.GetAnnotations()[ConsumedEventTypesAnnotationKey], ",") {
+		if v != "" {
+			existing = append(existing, v)
+		}
+	}
+	for _, v := range existing {
+		if v == key.Type {
+			return nil
+		}
+	}
+
+	updated := append(existing, key.Type)
+	sort.Strings(updated)
+
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, ConsumedEventTypesAnnotationKey, strings.Join(updated, ",")))
+	_, err := r.eventingClientSet.EventingV1beta1().Triggers(t.Namespace).Patch(ctx, t.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
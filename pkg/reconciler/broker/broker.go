@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	"knative.dev/eventing/pkg/apis/eventing/v1alpha1"
+	eventingclientset "knative.dev/eventing/pkg/client/clientset/versioned"
+	brokerreconciler "knative.dev/eventing/pkg/client/injection/reconciler/eventing/v1alpha1/broker"
+	eventinglisters "knative.dev/eventing/pkg/client/listers/eventing/v1beta1"
+	"knative.dev/pkg/logging"
+	reconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/resolver"
+)
+
+// Reconciler reconciles the delivery settings of a channel-based Broker: it
+// resolves Spec.Delivery.DeadLetterSink to a URI and makes sure every
+// Trigger of the Broker has an effective DeliverySpec that inherits the
+// Broker's retry/backoff/timeout/dead-letter-sink settings for whichever
+// fields the Trigger itself leaves unset. It does not create the Broker's
+// channels, filter or ingress.
+type Reconciler struct {
+	triggerLister     eventinglisters.TriggerLister
+	eventingClientSet eventingclientset.Interface
+	uriResolver       *resolver.URIResolver
+}
+
+var _ brokerreconciler.Interface = (*Reconciler)(nil)
+
+// ReconcileKind implements brokerreconciler.Interface.
+func (r *Reconciler) ReconcileKind(ctx context.Context, b *v1alpha1.Broker) reconciler.Event {
+	r.reconcileDeadLetterSink(ctx, b)
+
+	triggers, err := r.triggerLister.Triggers(b.Namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("listing triggers for broker %s/%s: %w", b.Namespace, b.Name, err)
+	}
+
+	logger := logging.FromContext(ctx)
+	for _, t := range triggers {
+		if t.Spec.Broker != b.Name {
+			continue
+		}
+		// t's effective delivery spec is its own, falling back to the
+		// Broker's for any field t leaves unset. Persist the result onto
+		// t so the fanout dispatcher
+		// (handler.NewRetryStateForTrigger) picks up the inherited Broker
+		// settings instead of only ever seeing t.Spec.Delivery.
+		effective := ResolveDelivery(b.Spec.Delivery, t.Spec.Delivery)
+		if err := r.patchEffectiveDelivery(ctx, t, effective); err != nil {
+			return fmt.Errorf("patching effective delivery spec for trigger %s/%s: %w", t.Namespace, t.Name, err)
+		}
+		logger.Debugw("resolved effective delivery spec for trigger",
+			"trigger", t.Name, "delivery", effective)
+	}
+
+	return nil
+}
+
+// patchEffectiveDelivery records spec as t's EffectiveDeliveryAnnotationKey
+// annotation, merge-patching only if it has changed.
+func (r *Reconciler) patchEffectiveDelivery(ctx context.Context, t *v1alpha1.Trigger, spec *eventingduckv1.DeliverySpec) error {
+	encoded, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("marshaling effective delivery spec: %w", err)
+	}
+	if t.GetAnnotations()[EffectiveDeliveryAnnotationKey] == string(encoded) {
+		return nil
+	}
+
+	annotationPatch, err := json.Marshal(string(encoded))
+	if err != nil {
+		return err
+	}
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%s}}}`, EffectiveDeliveryAnnotationKey, annotationPatch))
+	_, err = r.eventingClientSet.EventingV1alpha1().Triggers(t.Namespace).Patch(ctx, t.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// reconcileDeadLetterSink resolves b.Spec.Delivery.DeadLetterSink, if set,
+// to a URI and records the result on b.Status via the
+// DeadLetterSinkResolved condition.
+func (r *Reconciler) reconcileDeadLetterSink(ctx context.Context, b *v1alpha1.Broker) {
+	if b.Spec.Delivery == nil || b.Spec.Delivery.DeadLetterSink == nil {
+		return
+	}
+	dls := b.Spec.Delivery.DeadLetterSink
+
+	if dls.URI != nil && dls.Ref == nil {
+		MarkDeadLetterSinkResolved(&b.Status, dls.URI)
+		return
+	}
+
+	if r.uriResolver == nil {
+		MarkDeadLetterSinkNotResolved(&b.Status, "DeadLetterSinkResolverMissing", "no URI resolver configured")
+		return
+	}
+	uri, err := r.uriResolver.URIFromDestinationV1(ctx, *dls, b)
+	if err != nil {
+		MarkDeadLetterSinkNotResolved(&b.Status, "DeadLetterSinkResolveFailed", err.Error())
+		return
+	}
+	MarkDeadLetterSinkResolved(&b.Status, uri)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package broker reconciles the channel-based Broker, including
+// propagating its Spec.Delivery settings down onto Triggers and resolving
+// its dead-letter sink.
+package broker
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	"knative.dev/eventing/pkg/apis/eventing/v1alpha1"
+	"knative.dev/pkg/apis"
+)
+
+// DeadLetterSinkResolvedConditionType is the Broker status condition set
+// once Spec.Delivery.DeadLetterSink has been resolved to a URI.
+const DeadLetterSinkResolvedConditionType = "DeadLetterSinkResolved"
+
+// EffectiveDeliveryAnnotationKey is written on a Trigger by the Broker
+// reconciler, carrying the JSON-encoded result of ResolveDelivery(broker's
+// Spec.Delivery, trigger's Spec.Delivery). The fanout dispatcher reads it
+// via handler.NewRetryStateForTrigger so a Broker-level retry/backoff/
+// dead-letter-sink setting actually reaches live delivery, not just the
+// Trigger's own Spec.Delivery. The dispatcher does not apply Timeout.
+const EffectiveDeliveryAnnotationKey = "eventing.knative.dev/effectiveDelivery"
+
+// ResolveDelivery merges a Trigger- or Channel-level DeliverySpec on top of
+// the Broker's, so that a nested resource only needs to set the fields it
+// wants to override and otherwise inherits the Broker's retry count,
+// backoff policy, dead-letter sink, and timeout.
+func ResolveDelivery(brokerSpec, resourceSpec *eventingduckv1.DeliverySpec) *eventingduckv1.DeliverySpec {
+	if resourceSpec == nil {
+		return brokerSpec
+	}
+	if brokerSpec == nil {
+		return resourceSpec
+	}
+
+	merged := *resourceSpec
+	if merged.Retry == nil {
+		merged.Retry = brokerSpec.Retry
+	}
+	if merged.BackoffPolicy == nil {
+		merged.BackoffPolicy = brokerSpec.BackoffPolicy
+	}
+	if merged.BackoffDelay == nil {
+		merged.BackoffDelay = brokerSpec.BackoffDelay
+	}
+	if merged.Timeout == nil {
+		merged.Timeout = brokerSpec.Timeout
+	}
+	if merged.DeadLetterSink == nil {
+		merged.DeadLetterSink = brokerSpec.DeadLetterSink
+	}
+	return &merged
+}
+
+func setDeadLetterSinkCondition(status *v1alpha1.BrokerStatus, c apis.Condition) {
+	for i, existing := range status.Status.Conditions {
+		if existing.Type == c.Type {
+			status.Status.Conditions[i] = c
+			return
+		}
+	}
+	status.Status.Conditions = append(status.Status.Conditions, c)
+}
+
+// MarkDeadLetterSinkResolved records that Spec.Delivery.DeadLetterSink has
+// been resolved to uri.
+func MarkDeadLetterSinkResolved(status *v1alpha1.BrokerStatus, uri *apis.URL) {
+	setDeadLetterSinkCondition(status, apis.Condition{
+		Type:    DeadLetterSinkResolvedConditionType,
+		Status:  corev1.ConditionTrue,
+		Message: uri.String(),
+	})
+}
+
+// MarkDeadLetterSinkNotResolved records that Spec.Delivery.DeadLetterSink
+// could not be resolved, with reason/msg describing why.
+func MarkDeadLetterSinkNotResolved(status *v1alpha1.BrokerStatus, reason, msg string) {
+	setDeadLetterSinkCondition(status, apis.Condition{
+		Type:    DeadLetterSinkResolvedConditionType,
+		Status:  corev1.ConditionFalse,
+		Reason:  reason,
+		Message: msg,
+	})
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	"knative.dev/eventing/pkg/apis/eventing/v1alpha1"
+	"knative.dev/pkg/apis"
+)
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func TestResolveDelivery(t *testing.T) {
+	retry := int32Ptr(5)
+	policy := eventingduckv1.BackoffPolicyExponential
+	brokerSpec := &eventingduckv1.DeliverySpec{
+		Retry:         retry,
+		BackoffPolicy: &policy,
+	}
+
+	cases := map[string]struct {
+		brokerSpec, resourceSpec *eventingduckv1.DeliverySpec
+		want                     *eventingduckv1.DeliverySpec
+	}{
+		"resource nil falls back to broker": {
+			brokerSpec:   brokerSpec,
+			resourceSpec: nil,
+			want:         brokerSpec,
+		},
+		"broker nil falls back to resource": {
+			brokerSpec:   nil,
+			resourceSpec: &eventingduckv1.DeliverySpec{Retry: int32Ptr(1)},
+			want:         &eventingduckv1.DeliverySpec{Retry: int32Ptr(1)},
+		},
+		"resource overrides only what it sets": {
+			brokerSpec:   brokerSpec,
+			resourceSpec: &eventingduckv1.DeliverySpec{Retry: int32Ptr(1)},
+			want: &eventingduckv1.DeliverySpec{
+				Retry:         int32Ptr(1),
+				BackoffPolicy: &policy,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ResolveDelivery(tc.brokerSpec, tc.resourceSpec)
+			if got == tc.want {
+				return
+			}
+			if got == nil || tc.want == nil {
+				t.Fatalf("ResolveDelivery() = %v, want %v", got, tc.want)
+			}
+			if (got.Retry == nil) != (tc.want.Retry == nil) || (got.Retry != nil && *got.Retry != *tc.want.Retry) {
+				t.Errorf("Retry = %v, want %v", got.Retry, tc.want.Retry)
+			}
+			if (got.BackoffPolicy == nil) != (tc.want.BackoffPolicy == nil) {
+				t.Errorf("BackoffPolicy = %v, want %v", got.BackoffPolicy, tc.want.BackoffPolicy)
+			}
+		})
+	}
+}
+
+func TestMarkDeadLetterSink(t *testing.T) {
+	status := &v1alpha1.BrokerStatus{}
+
+	MarkDeadLetterSinkResolved(status, &apis.URL{Scheme: "http", Host: "dls.example.com"})
+	c := findCondition(status, DeadLetterSinkResolvedConditionType)
+	if c == nil || c.Status != corev1.ConditionTrue {
+		t.Fatalf("after MarkDeadLetterSinkResolved, condition = %v, want True", c)
+	}
+	if c.Message != "http://dls.example.com" {
+		t.Errorf("condition message = %q, want resolved URI", c.Message)
+	}
+
+	MarkDeadLetterSinkNotResolved(status, "ResolveFailed", "could not resolve ref")
+	c = findCondition(status, DeadLetterSinkResolvedConditionType)
+	if c == nil || c.Status != corev1.ConditionFalse {
+		t.Fatalf("after MarkDeadLetterSinkNotResolved, condition = %v, want False", c)
+	}
+	if c.Reason != "ResolveFailed" {
+		t.Errorf("condition reason = %q, want %q", c.Reason, "ResolveFailed")
+	}
+
+	// Re-marking must update the existing condition in place, not append a
+	// second entry.
+	if got := len(status.Status.Conditions); got != 1 {
+		t.Fatalf("len(Conditions) = %d, want 1", got)
+	}
+}
+
+func findCondition(status *v1alpha1.BrokerStatus, t apis.ConditionType) *apis.Condition {
+	for i, c := range status.Status.Conditions {
+		if c.Type == t {
+			return &status.Status.Conditions[i]
+		}
+	}
+	return nil
+}
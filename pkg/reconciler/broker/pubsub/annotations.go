@@ -0,0 +1,28 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+const (
+	// ProjectAnnotationKey names the GCP project a GCPPubSub-class Broker
+	// provisions its topic and subscriptions in.
+	ProjectAnnotationKey = "eventing.knative.dev/broker.pubsub.project"
+
+	// TopicAnnotationKey optionally overrides the topic name a
+	// GCPPubSub-class Broker provisions, instead of the deterministic
+	// name returned by TopicName.
+	TopicAnnotationKey = "eventing.knative.dev/broker.pubsub.topic"
+)
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/pubsub"
+	"google.golang.org/api/option"
+)
+
+// gcpPubSubClient is the default PubSubClient, backed by the real Cloud
+// Pub/Sub admin client.
+type gcpPubSubClient struct {
+	client *pubsub.Client
+}
+
+// newPubSubClient constructs a PubSubClient scoped to project, authenticated
+// with the given service-account JSON credentials.
+func newPubSubClient(ctx context.Context, project string, creds []byte) (PubSubClient, error) {
+	c, err := pubsub.NewClient(ctx, project, option.WithCredentialsJSON(creds))
+	if err != nil {
+		return nil, fmt.Errorf("creating pubsub client for project %q: %w", project, err)
+	}
+	return &gcpPubSubClient{client: c}, nil
+}
+
+func (c *gcpPubSubClient) EnsureTopic(ctx context.Context, topic string) error {
+	t := c.client.Topic(topic)
+	ok, err := t.Exists(ctx)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+	_, err = c.client.CreateTopic(ctx, topic)
+	return err
+}
+
+func (c *gcpPubSubClient) EnsureSubscription(ctx context.Context, topic, subscription, pushEndpoint string) error {
+	sub := c.client.Subscription(subscription)
+	ok, err := sub.Exists(ctx)
+	if err != nil {
+		return err
+	}
+	cfg := pubsub.SubscriptionConfig{
+		Topic:      c.client.Topic(topic),
+		PushConfig: pubsub.PushConfig{Endpoint: pushEndpoint},
+	}
+	if ok {
+		_, err = sub.Update(ctx, pubsub.SubscriptionConfigToUpdate{PushConfig: &cfg.PushConfig})
+		return err
+	}
+	_, err = c.client.CreateSubscription(ctx, subscription, cfg)
+	return err
+}
+
+func (c *gcpPubSubClient) DeleteTopic(ctx context.Context, topic string) error {
+	return c.client.Topic(topic).Delete(ctx)
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	secretinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/secret"
+
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	brokerinformer "knative.dev/eventing/pkg/client/injection/informers/eventing/v1alpha1/broker"
+	triggerinformer "knative.dev/eventing/pkg/client/injection/informers/eventing/v1beta1/trigger"
+	brokerreconciler "knative.dev/eventing/pkg/client/injection/reconciler/eventing/v1alpha1/broker"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
+)
+
+// NewController creates a Reconciler for Brokers annotated with the
+// GCPPubSub class and wraps it in a controller.Impl that watches Brokers of
+// that class and the Triggers pointed at them. The credentials Secrets are
+// read through a lister; a change to a Secret does not enqueue its Broker.
+func NewController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+	logger := logging.FromContext(ctx)
+
+	brokerInformer := brokerinformer.Get(ctx)
+	triggerInformer := triggerinformer.Get(ctx)
+	secretInformer := secretinformer.Get(ctx)
+
+	r := &Reconciler{
+		secretLister:        secretInformer.Lister(),
+		triggerLister:       triggerInformer.Lister(),
+		pubsubClientCreator: newPubSubClient,
+	}
+
+	impl := brokerreconciler.NewImpl(ctx, r, BrokerClass)
+
+	logger.Info("setting up GCPPubSub broker controller event handlers")
+
+	brokerInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: pkgreconciler.AnnotationFilterFunc(brokerreconciler.ClassAnnotationKey, BrokerClass, false),
+		Handler:    controller.HandleAll(impl.Enqueue),
+	})
+
+	// Re-enqueue a Trigger's Broker whenever the Trigger changes, so newly
+	// resolved subscriber addresses get a subscription provisioned promptly.
+	triggerInformer.Informer().AddEventHandler(controller.HandleAll(func(obj interface{}) {
+		t, ok := obj.(*eventingv1beta1.Trigger)
+		if !ok {
+			return
+		}
+		impl.EnqueueKey(types.NamespacedName{Namespace: t.Namespace, Name: t.Spec.Broker})
+	}))
+
+	return impl
+}
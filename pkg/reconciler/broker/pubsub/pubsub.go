@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pubsub implements the "GCPPubSub" Broker class: a Broker backed by
+// a single Google Cloud Pub/Sub topic, with one subscription provisioned per
+// Trigger, rather than an in-cluster channel.
+package pubsub
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"knative.dev/eventing/pkg/apis/eventing/v1alpha1"
+	brokerreconciler "knative.dev/eventing/pkg/client/injection/reconciler/eventing/v1alpha1/broker"
+	eventinglisters "knative.dev/eventing/pkg/client/listers/eventing/v1beta1"
+	reconciler "knative.dev/pkg/reconciler"
+)
+
+// BrokerClass is the value of the eventing.knative.dev/broker.class
+// annotation that selects this reconciler.
+const BrokerClass = "GCPPubSub"
+
+// Reconciler reconciles Brokers annotated with the GCPPubSub broker class by
+// provisioning a Pub/Sub topic per Broker and a subscription per Trigger.
+type Reconciler struct {
+	// secretLister resolves the referenced GCP credentials Secret for a
+	// Broker's project.
+	secretLister corev1listers.SecretLister
+
+	// triggerLister lists the Triggers of a Broker, so a subscription can
+	// be provisioned for each.
+	triggerLister eventinglisters.TriggerLister
+
+	// pubsubClientCreator constructs a project-scoped Pub/Sub admin client
+	// from resolved credentials. It is a field (rather than a package
+	// function) so tests can substitute a fake.
+	pubsubClientCreator func(ctx context.Context, project string, creds []byte) (PubSubClient, error)
+}
+
+// PubSubClient is the subset of the Pub/Sub admin API the reconciler needs,
+// kept narrow so it can be faked in tests without vendoring the full SDK
+// surface.
+type PubSubClient interface {
+	EnsureTopic(ctx context.Context, topic string) error
+	EnsureSubscription(ctx context.Context, topic, subscription, pushEndpoint string) error
+	DeleteTopic(ctx context.Context, topic string) error
+}
+
+var _ brokerreconciler.Interface = (*Reconciler)(nil)
+
+// ReconcileKind implements brokerreconciler.Interface.
+func (r *Reconciler) ReconcileKind(ctx context.Context, b *v1alpha1.Broker) reconciler.Event {
+	project := b.GetAnnotations()[ProjectAnnotationKey]
+	if project == "" {
+		return fmt.Errorf("broker %s/%s is missing the %s annotation required by the %s class", b.Namespace, b.Name, ProjectAnnotationKey, BrokerClass)
+	}
+
+	if b.Spec.Config == nil || b.Spec.Config.Name == "" {
+		MarkBrokerClassUnresolved(&b.Status, "SecretMissing", "spec.config must reference the Secret holding GCP credentials")
+		return fmt.Errorf("broker %s/%s has no spec.config referencing a credentials Secret", b.Namespace, b.Name)
+	}
+
+	secret, err := r.secretLister.Secrets(b.Namespace).Get(b.Spec.Config.Name)
+	if err != nil {
+		MarkBrokerClassUnresolved(&b.Status, "SecretNotFound", "failed to get credentials secret: %v", err)
+		return err
+	}
+
+	client, err := r.pubsubClientCreator(ctx, project, secret.Data["key.json"])
+	if err != nil {
+		MarkBrokerClassUnresolved(&b.Status, "ClientCreateFailed", "failed to create Pub/Sub client: %v", err)
+		return err
+	}
+
+	topic := TopicName(b)
+	if err := client.EnsureTopic(ctx, topic); err != nil {
+		MarkBrokerClassUnresolved(&b.Status, "TopicCreateFailed", "failed to ensure topic %q: %v", topic, err)
+		return err
+	}
+
+	if err := r.reconcileTriggerSubscriptions(ctx, b, client, topic); err != nil {
+		MarkBrokerClassUnresolved(&b.Status, "SubscriptionCreateFailed", "failed to ensure trigger subscriptions: %v", err)
+		return err
+	}
+
+	MarkBrokerClassReady(&b.Status)
+
+	return nil
+}
+
+// reconcileTriggerSubscriptions ensures every Trigger pointed at b has a
+// Pub/Sub subscription on topic, pushing to that Trigger's subscriber.
+func (r *Reconciler) reconcileTriggerSubscriptions(ctx context.Context, b *v1alpha1.Broker, client PubSubClient, topic string) error {
+	triggers, err := r.triggerLister.Triggers(b.Namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("listing triggers for broker %s/%s: %w", b.Namespace, b.Name, err)
+	}
+
+	for _, t := range triggers {
+		if t.Spec.Broker != b.Name {
+			continue
+		}
+		pushEndpoint := t.Status.SubscriberURI
+		if pushEndpoint == nil {
+			// The Trigger's subscriber address hasn't resolved yet; it
+			// will be retried once the Trigger reconciler resolves it and
+			// this Broker gets re-enqueued.
+			continue
+		}
+		sub := SubscriptionName(t.Namespace, t.Name)
+		if err := client.EnsureSubscription(ctx, topic, sub, pushEndpoint.String()); err != nil {
+			return fmt.Errorf("ensuring subscription %q for trigger %s/%s: %w", sub, t.Namespace, t.Name, err)
+		}
+	}
+	return nil
+}
+
+// TopicName returns the Pub/Sub topic name provisioned for a Broker: the
+// TopicAnnotationKey override if one is set, otherwise the deterministic
+// knative-broker-<ns>-<name> name.
+func TopicName(b *v1alpha1.Broker) string {
+	if topic := b.GetAnnotations()[TopicAnnotationKey]; topic != "" {
+		return topic
+	}
+	return fmt.Sprintf("knative-broker-%s-%s", b.Namespace, b.Name)
+}
+
+// SubscriptionName returns the deterministic Pub/Sub subscription name
+// provisioned for a Trigger against its Broker's topic.
+func SubscriptionName(triggerNamespace, triggerName string) string {
+	return fmt.Sprintf("knative-trigger-%s-%s", triggerNamespace, triggerName)
+}
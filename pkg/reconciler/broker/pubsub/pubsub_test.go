@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/eventing/pkg/apis/eventing/v1alpha1"
+	"knative.dev/pkg/apis"
+)
+
+func TestTopicName(t *testing.T) {
+	cases := map[string]struct {
+		b    *v1alpha1.Broker
+		want string
+	}{
+		"no override derives deterministic name": {
+			b:    &v1alpha1.Broker{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "default"}},
+			want: "knative-broker-ns-default",
+		},
+		"annotation overrides the name": {
+			b: &v1alpha1.Broker{ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "ns",
+				Name:        "default",
+				Annotations: map[string]string{TopicAnnotationKey: "my-custom-topic"},
+			}},
+			want: "my-custom-topic",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := TopicName(tc.b); got != tc.want {
+				t.Errorf("TopicName() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSubscriptionName(t *testing.T) {
+	if got, want := SubscriptionName("ns", "my-trigger"), "knative-trigger-ns-my-trigger"; got != want {
+		t.Errorf("SubscriptionName() = %q, want %q", got, want)
+	}
+}
+
+func TestMarkBrokerClass(t *testing.T) {
+	status := &v1alpha1.BrokerStatus{}
+
+	MarkBrokerClassUnresolved(status, "SecretMissing", "spec.config must reference %s", "a Secret")
+	c := findCondition(status, BrokerClassReadyConditionType)
+	if c == nil || c.Reason != "SecretMissing" {
+		t.Fatalf("condition after MarkBrokerClassUnresolved = %v, want reason SecretMissing", c)
+	}
+	if c.Message != "spec.config must reference a Secret" {
+		t.Errorf("condition message = %q, want formatted message", c.Message)
+	}
+
+	MarkBrokerClassReady(status)
+	c = findCondition(status, BrokerClassReadyConditionType)
+	if c == nil {
+		t.Fatal("condition after MarkBrokerClassReady = nil")
+	}
+	if got := len(status.Status.Conditions); got != 1 {
+		t.Fatalf("len(Conditions) = %d, want 1 (condition updated in place)", got)
+	}
+}
+
+func findCondition(status *v1alpha1.BrokerStatus, t apis.ConditionType) *apis.Condition {
+	for i, c := range status.Status.Conditions {
+		if c.Type == t {
+			return &status.Status.Conditions[i]
+		}
+	}
+	return nil
+}
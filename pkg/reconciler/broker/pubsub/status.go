@@ -0,0 +1,62 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/eventing/pkg/apis/eventing/v1alpha1"
+	"knative.dev/pkg/apis"
+)
+
+// BrokerClassReadyConditionType is the Broker status condition the
+// GCPPubSub broker class reports its own provisioning readiness on. It
+// mirrors how pkg/reconciler/broker tracks DeadLetterSinkResolved: a
+// package-level helper operating directly on *v1alpha1.BrokerStatus,
+// since BrokerStatus itself defines no GCPPubSub-specific methods.
+const BrokerClassReadyConditionType = "GCPPubSubClassReady"
+
+func setBrokerClassCondition(status *v1alpha1.BrokerStatus, c apis.Condition) {
+	for i, existing := range status.Status.Conditions {
+		if existing.Type == c.Type {
+			status.Status.Conditions[i] = c
+			return
+		}
+	}
+	status.Status.Conditions = append(status.Status.Conditions, c)
+}
+
+// MarkBrokerClassReady records that the GCPPubSub class has finished
+// provisioning the Broker's topic and its Triggers' subscriptions.
+func MarkBrokerClassReady(status *v1alpha1.BrokerStatus) {
+	setBrokerClassCondition(status, apis.Condition{
+		Type:   BrokerClassReadyConditionType,
+		Status: corev1.ConditionTrue,
+	})
+}
+
+// MarkBrokerClassUnresolved records that the GCPPubSub class could not
+// finish provisioning, with reason and a printf-style message.
+func MarkBrokerClassUnresolved(status *v1alpha1.BrokerStatus, reason, msgFormat string, args ...interface{}) {
+	setBrokerClassCondition(status, apis.Condition{
+		Type:    BrokerClassReadyConditionType,
+		Status:  corev1.ConditionFalse,
+		Reason:  reason,
+		Message: fmt.Sprintf(msgFormat, args...),
+	})
+}
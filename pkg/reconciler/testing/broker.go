@@ -18,14 +18,20 @@ package testing
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
 	"knative.dev/eventing/pkg/apis/eventing/v1alpha1"
 	messagingv1beta1 "knative.dev/eventing/pkg/apis/messaging/v1beta1"
 	"knative.dev/eventing/pkg/client/injection/reconciler/eventing/v1alpha1/broker"
+	"knative.dev/eventing/pkg/eventing/discovery"
+	brokerreconciler "knative.dev/eventing/pkg/reconciler/broker"
+	"knative.dev/eventing/pkg/reconciler/broker/pubsub"
 	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
 
 // BrokerOption enables further configuration of a Broker.
@@ -185,3 +191,152 @@ func WithBrokerClass(bc string) BrokerOption {
 		b.SetAnnotations(annotations)
 	}
 }
+
+// brokerDelivery returns the Broker's Spec.Delivery, initializing it if needed.
+func brokerDelivery(b *v1alpha1.Broker) *eventingduckv1.DeliverySpec {
+	if b.Spec.Delivery == nil {
+		b.Spec.Delivery = &eventingduckv1.DeliverySpec{}
+	}
+	return b.Spec.Delivery
+}
+
+// WithBrokerPubSubProject sets the annotation that tells the GCPPubSub
+// Broker class which GCP project to provision its topic and subscriptions
+// in.
+func WithBrokerPubSubProject(id string) BrokerOption {
+	return func(b *v1alpha1.Broker) {
+		annotations := b.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+		annotations[pubsub.ProjectAnnotationKey] = id
+		b.SetAnnotations(annotations)
+	}
+}
+
+// WithBrokerPubSubTopic overrides the Pub/Sub topic name the GCPPubSub
+// Broker class would otherwise derive deterministically from the Broker's
+// namespace/name.
+func WithBrokerPubSubTopic(name string) BrokerOption {
+	return func(b *v1alpha1.Broker) {
+		annotations := b.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+		annotations[pubsub.TopicAnnotationKey] = name
+		b.SetAnnotations(annotations)
+	}
+}
+
+// pubSubTopicConditionType is the Broker status condition that
+// WithPubSubTopicReady and WithPubSubTopicFailed set. The GCPPubSub
+// reconciler does not write it; it reports provisioning, topic included, on
+// pubsub.BrokerClassReadyConditionType.
+const pubSubTopicConditionType = "PubSubTopicReady"
+
+func setPubSubTopicCondition(b *v1alpha1.Broker, status corev1.ConditionStatus, reason, msg string) {
+	c := apis.Condition{
+		Type:    pubSubTopicConditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: msg,
+	}
+	for i, existing := range b.Status.Status.Conditions {
+		if existing.Type == c.Type {
+			b.Status.Status.Conditions[i] = c
+			return
+		}
+	}
+	b.Status.Status.Conditions = append(b.Status.Status.Conditions, c)
+}
+
+// WithPubSubTopicReady marks the Broker's PubSubTopicReady condition True.
+func WithPubSubTopicReady() BrokerOption {
+	return func(b *v1alpha1.Broker) {
+		setPubSubTopicCondition(b, corev1.ConditionTrue, "", "")
+	}
+}
+
+// WithPubSubTopicFailed marks the Broker's PubSubTopicReady condition False
+// with the given reason/message.
+func WithPubSubTopicFailed(reason, msg string) BrokerOption {
+	return func(b *v1alpha1.Broker) {
+		setPubSubTopicCondition(b, corev1.ConditionFalse, reason, msg)
+	}
+}
+
+// WithBrokerDeliveryRetry sets the Broker's Spec.Delivery.Retry to count.
+func WithBrokerDeliveryRetry(count int32) BrokerOption {
+	return func(b *v1alpha1.Broker) {
+		brokerDelivery(b).Retry = &count
+	}
+}
+
+// WithBrokerDeliveryBackoff sets the Broker's Spec.Delivery.BackoffPolicy and BackoffDelay.
+func WithBrokerDeliveryBackoff(policy eventingduckv1.BackoffPolicyType, delay string) BrokerOption {
+	return func(b *v1alpha1.Broker) {
+		d := brokerDelivery(b)
+		d.BackoffPolicy = &policy
+		d.BackoffDelay = &delay
+	}
+}
+
+// WithBrokerDeliveryDeadLetterSink sets the Broker's Spec.Delivery.DeadLetterSink to a ref,
+// an http URL whose host is uri (via apis.HTTP), or both.
+func WithBrokerDeliveryDeadLetterSink(ref *duckv1.KReference, uri string) BrokerOption {
+	return func(b *v1alpha1.Broker) {
+		dls := &duckv1.Destination{Ref: ref}
+		if uri != "" {
+			dls.URI = apis.HTTP(uri)
+		}
+		brokerDelivery(b).DeadLetterSink = dls
+	}
+}
+
+// WithBrokerDeliveryTimeout sets the Broker's Spec.Delivery.Timeout.
+func WithBrokerDeliveryTimeout(d string) BrokerOption {
+	return func(b *v1alpha1.Broker) {
+		brokerDelivery(b).Timeout = &d
+	}
+}
+
+// WithBrokerDiscoveredEventTypes sets the Broker's discovered-event-types
+// annotation, as written by the EventType discovery controller, to the
+// comma-separated list of types.
+func WithBrokerDiscoveredEventTypes(types ...string) BrokerOption {
+	return func(b *v1alpha1.Broker) {
+		annotations := b.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+		annotations[discovery.DiscoveredEventTypesAnnotationKey] = strings.Join(types, ",")
+		b.SetAnnotations(annotations)
+	}
+}
+
+// WithBrokerProtocol sets the Broker's Spec.Protocol to the named protocol
+// binding (e.g. "HTTP"), letting tests exercise brokers over non-HTTP
+// transports.
+func WithBrokerProtocol(binding string) BrokerOption {
+	return func(b *v1alpha1.Broker) {
+		b.Spec.Protocol = binding
+	}
+}
+
+// WithBrokerDeadLetterSinkResolved calls brokerreconciler.MarkDeadLetterSinkResolved,
+// the same helper the Broker reconciler uses, recording the fully resolved
+// dead-letter sink URI.
+func WithBrokerDeadLetterSinkResolved(uri *apis.URL) BrokerOption {
+	return func(b *v1alpha1.Broker) {
+		brokerreconciler.MarkDeadLetterSinkResolved(&b.Status, uri)
+	}
+}
+
+// WithBrokerDeadLetterSinkNotResolved calls brokerreconciler.MarkDeadLetterSinkNotResolved,
+// the same helper the Broker reconciler uses, recording that the
+// dead-letter sink could not be resolved.
+func WithBrokerDeadLetterSinkNotResolved(reason, msg string) BrokerOption {
+	return func(b *v1alpha1.Broker) {
+		brokerreconciler.MarkDeadLetterSinkNotResolved(&b.Status, reason, msg)
+	}
+}
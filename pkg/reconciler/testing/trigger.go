@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	"knative.dev/eventing/pkg/apis/eventing/v1alpha1"
+	"knative.dev/eventing/pkg/broker/handler"
+	"knative.dev/eventing/pkg/eventing/discovery"
+)
+
+// TriggerOption enables further configuration of a Trigger.
+type TriggerOption func(*v1alpha1.Trigger)
+
+// NewTrigger creates a Trigger with TriggerOptions.
+func NewTrigger(name, namespace, broker string, o ...TriggerOption) *v1alpha1.Trigger {
+	t := &v1alpha1.Trigger{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Spec: v1alpha1.TriggerSpec{
+			Broker: broker,
+		},
+	}
+	for _, opt := range o {
+		opt(t)
+	}
+	t.SetDefaults(context.Background())
+	return t
+}
+
+// triggerDelivery returns the Trigger's Spec.Delivery, initializing it if
+// needed.
+func triggerDelivery(t *v1alpha1.Trigger) *eventingduckv1.DeliverySpec {
+	if t.Spec.Delivery == nil {
+		t.Spec.Delivery = &eventingduckv1.DeliverySpec{}
+	}
+	return t.Spec.Delivery
+}
+
+// WithTriggerDeliveryRetry sets the Trigger's Spec.Delivery.Retry to count,
+// overriding whatever retry count its Broker would otherwise supply.
+func WithTriggerDeliveryRetry(count int32) TriggerOption {
+	return func(t *v1alpha1.Trigger) {
+		triggerDelivery(t).Retry = &count
+	}
+}
+
+// WithTriggerBackoff sets the Trigger's Spec.Delivery.BackoffPolicy and
+// BackoffDelay.
+func WithTriggerBackoff(policy eventingduckv1.BackoffPolicyType, delay string) TriggerOption {
+	return func(t *v1alpha1.Trigger) {
+		d := triggerDelivery(t)
+		d.BackoffPolicy = &policy
+		d.BackoffDelay = &delay
+	}
+}
+
+// WithTriggerRetryAfterMax sets the upper bound the fanout dispatcher clamps
+// any subscriber Retry-After header against. handler.NewRetryStateForTrigger
+// reads this same annotation when building a Trigger's RetryState.
+func WithTriggerRetryAfterMax(d string) TriggerOption {
+	return func(t *v1alpha1.Trigger) {
+		annotations := t.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+		annotations[handler.RetryAfterMaxAnnotationKey] = d
+		t.SetAnnotations(annotations)
+	}
+}
+
+// WithTriggerConsumedEventTypes sets the Trigger's consumed-event-types
+// annotation, as written by the EventType discovery controller, to the
+// comma-separated list of types.
+func WithTriggerConsumedEventTypes(types ...string) TriggerOption {
+	return func(t *v1alpha1.Trigger) {
+		annotations := t.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+		annotations[discovery.ConsumedEventTypesAnnotationKey] = strings.Join(types, ",")
+		t.SetAnnotations(annotations)
+	}
+}
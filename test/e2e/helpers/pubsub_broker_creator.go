@@ -0,0 +1,38 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"knative.dev/eventing/pkg/reconciler/broker/pubsub"
+	"knative.dev/eventing/test/lib"
+	"knative.dev/eventing/test/lib/resources"
+)
+
+// PubSubBrokerCreator returns a BrokerCreator that creates a Broker of the
+// GCPPubSub class provisioning its topic in project, for use with
+// TestTriggerNoBroker and other conformance helpers that take a
+// BrokerCreator.
+func PubSubBrokerCreator(project string) BrokerCreator {
+	return func(client *lib.Client) string {
+		name := "testbroker"
+		client.CreateBrokerV1Beta1OrFail(name,
+			resources.WithBrokerClassForBrokerV1Beta1(pubsub.BrokerClass),
+			resources.WithBrokerPubSubProjectForBrokerV1Beta1(project),
+		)
+		return name
+	}
+}
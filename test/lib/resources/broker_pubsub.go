@@ -0,0 +1,36 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/eventing/pkg/reconciler/broker/pubsub"
+)
+
+// WithBrokerPubSubProjectForBrokerV1Beta1 sets the annotation that tells the
+// GCPPubSub Broker class which GCP project to provision its topic and
+// subscriptions in.
+func WithBrokerPubSubProjectForBrokerV1Beta1(project string) func(*eventingv1beta1.Broker) {
+	return func(b *eventingv1beta1.Broker) {
+		annotations := b.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+		annotations[pubsub.ProjectAnnotationKey] = project
+		b.SetAnnotations(annotations)
+	}
+}